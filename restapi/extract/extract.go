@@ -0,0 +1,90 @@
+// Package extract turns fetched web content into clean, prompt-ready text.
+// getWebContent used to always strip script/style/nav/header/footer from
+// body and call it done, which works for simple articles and nothing else;
+// this package lets callers pick (or let content-type/url pick for them)
+// the extractor that actually fits what was fetched.
+package extract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// Document is what an Extractor turns a response body into.
+type Document struct {
+	Title   string
+	Content string
+}
+
+// Fetcher fetches a URL's body and content type. Extractors that need to
+// follow links inside the document they're parsing (sitemaps) take one of
+// these from the context passed to Extract, rather than reaching for
+// net/http directly, so they inherit the caller's cancellation and any
+// per-request fetch overrides.
+type Fetcher func(ctx context.Context, rawURL string) (contentType string, body io.ReadCloser, err error)
+
+// Extractor turns a fetched response body into a Document. CanHandle lets
+// the Registry pick the right one without sniffing content twice.
+type Extractor interface {
+	Name() string
+	CanHandle(contentType, rawURL string) bool
+	Extract(ctx context.Context, r io.Reader, u *url.URL) (Document, error)
+}
+
+// Registry holds extractors in priority order; the first one whose
+// CanHandle returns true wins unless the caller forces one by name.
+type Registry struct {
+	extractors []Extractor
+}
+
+// NewRegistry builds a registry with fabric's default extractors: PDF
+// (matched by content type or a .pdf URL) and sitemap expansion (matched by
+// URL only), feed, readability, and a catch-all plain-text fallback tried
+// last.
+func NewRegistry() *Registry {
+	r := &Registry{}
+	r.Register(NewPDFExtractor())
+	r.Register(NewSitemapExtractor(r))
+	r.Register(NewFeedExtractor())
+	r.Register(NewReadabilityExtractor())
+	r.Register(NewPlainTextExtractor())
+	return r
+}
+
+// Register appends e to the registry, to be tried after anything already
+// registered.
+func (r *Registry) Register(e Extractor) {
+	r.extractors = append(r.extractors, e)
+}
+
+// Extract picks an extractor by content type/url, or by name when forced,
+// and runs it against body. fetch is made available (via ctx) to
+// extractors that need to fetch further URLs, and ctx's cancellation is
+// honored by any such nested fetch.
+func (r *Registry) Extract(ctx context.Context, name, contentType, rawURL string, fetch Fetcher, body io.Reader) (Document, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Document{}, fmt.Errorf("parsing url %q: %w", rawURL, err)
+	}
+
+	ctx = withFetcher(ctx, fetch)
+
+	if name != "" {
+		for _, e := range r.extractors {
+			if e.Name() == name {
+				return e.Extract(ctx, body, u)
+			}
+		}
+		return Document{}, fmt.Errorf("unknown extractor %q", name)
+	}
+
+	for _, e := range r.extractors {
+		if e.CanHandle(contentType, rawURL) {
+			return e.Extract(ctx, body, u)
+		}
+	}
+
+	return Document{}, fmt.Errorf("no extractor registered for content type %q", contentType)
+}
@@ -0,0 +1,59 @@
+package extract
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func noopFetch(_ context.Context, _ string) (string, io.ReadCloser, error) {
+	return "", nil, nil
+}
+
+func TestRegistryRoutesByContentType(t *testing.T) {
+	reg := NewRegistry()
+
+	cases := []struct {
+		name        string
+		contentType string
+		rawURL      string
+		body        string
+		wantTitle   string
+	}{
+		{"readability", "text/html", "https://example.com/article", "<html><title>T</title><body><p>hi</p></body></html>", "T"},
+		{"plaintext fallback", "text/plain", "https://example.com/notes.txt", "just text", "https://example.com/notes.txt"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := reg.Extract(context.Background(), "", tc.contentType, tc.rawURL, noopFetch, strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if doc.Title != tc.wantTitle {
+				t.Fatalf("expected title %q, got %q", tc.wantTitle, doc.Title)
+			}
+		})
+	}
+}
+
+func TestRegistryForcesExtractorByName(t *testing.T) {
+	reg := NewRegistry()
+
+	doc, err := reg.Extract(context.Background(), "plaintext", "text/html", "https://example.com/article", noopFetch, strings.NewReader("<p>raw</p>"))
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if doc.Content != "<p>raw</p>" {
+		t.Fatalf("expected forced plaintext extractor to skip HTML parsing, got %q", doc.Content)
+	}
+}
+
+func TestRegistryUnknownForcedExtractorErrors(t *testing.T) {
+	reg := NewRegistry()
+
+	if _, err := reg.Extract(context.Background(), "does-not-exist", "", "https://example.com", noopFetch, strings.NewReader("")); err == nil {
+		t.Fatal("expected an error for an unknown forced extractor name")
+	}
+}
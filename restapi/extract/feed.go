@@ -0,0 +1,66 @@
+package extract
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// feed covers both RSS 2.0 (channel>item) and Atom (entry) shapes; the
+// element names don't collide so one struct decodes either.
+type feed struct {
+	Title   string     `xml:"channel>title"`
+	Items   []feedItem `xml:"channel>item"`
+	Entries []feedItem `xml:"entry"`
+}
+
+type feedItem struct {
+	Title       string `xml:"title"`
+	Description string `xml:"description"`
+	Summary     string `xml:"summary"`
+}
+
+// FeedExtractor turns an RSS or Atom feed into concatenated item
+// titles+summaries.
+type FeedExtractor struct{}
+
+func NewFeedExtractor() *FeedExtractor { return &FeedExtractor{} }
+
+func (e *FeedExtractor) Name() string { return "feed" }
+
+func (e *FeedExtractor) CanHandle(contentType, rawURL string) bool {
+	if strings.Contains(contentType, "rss") || strings.Contains(contentType, "atom") {
+		return true
+	}
+	return strings.Contains(contentType, "xml") && !strings.Contains(strings.ToLower(rawURL), "sitemap")
+}
+
+func (e *FeedExtractor) Extract(_ context.Context, r io.Reader, u *url.URL) (Document, error) {
+	var f feed
+	if err := xml.NewDecoder(r).Decode(&f); err != nil {
+		return Document{}, err
+	}
+
+	items := f.Items
+	if len(items) == 0 {
+		items = f.Entries
+	}
+
+	var b strings.Builder
+	for _, item := range items {
+		summary := item.Description
+		if summary == "" {
+			summary = item.Summary
+		}
+		b.WriteString(item.Title)
+		if summary != "" {
+			b.WriteString(": ")
+			b.WriteString(summary)
+		}
+		b.WriteString("\n\n")
+	}
+
+	return Document{Title: f.Title, Content: strings.TrimSpace(b.String())}, nil
+}
@@ -0,0 +1,20 @@
+package extract
+
+import "context"
+
+// fetcherContextKey is the unexported key Registry.Extract uses to thread
+// the caller's Fetcher through ctx, so extractors that fetch further URLs
+// (sitemap) pick up the same cancellation and per-request fetch options as
+// the top-level request instead of a package-level default.
+type fetcherContextKey struct{}
+
+func withFetcher(ctx context.Context, fetch Fetcher) context.Context {
+	return context.WithValue(ctx, fetcherContextKey{}, fetch)
+}
+
+// fetcherFromContext returns the Fetcher stashed by Registry.Extract, or
+// nil if none was set (e.g. Extract called directly in a test).
+func fetcherFromContext(ctx context.Context) Fetcher {
+	fetch, _ := ctx.Value(fetcherContextKey{}).(Fetcher)
+	return fetch
+}
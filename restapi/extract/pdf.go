@@ -0,0 +1,51 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PDFExtractor pulls plain text out of application/pdf bodies. goquery only
+// understands HTML, so PDFs need their own extractor entirely.
+type PDFExtractor struct{}
+
+func NewPDFExtractor() *PDFExtractor { return &PDFExtractor{} }
+
+func (e *PDFExtractor) Name() string { return "pdf" }
+
+func (e *PDFExtractor) CanHandle(contentType, rawURL string) bool {
+	return strings.Contains(contentType, "application/pdf") || strings.HasSuffix(strings.ToLower(rawURL), ".pdf")
+}
+
+func (e *PDFExtractor) Extract(_ context.Context, r io.Reader, u *url.URL) (Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Document{}, err
+	}
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Document{}, err
+	}
+
+	var b strings.Builder
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			continue
+		}
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+
+	return Document{Title: u.String(), Content: strings.TrimSpace(b.String())}, nil
+}
@@ -0,0 +1,27 @@
+package extract
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// PlainTextExtractor is the catch-all fallback: it returns the body as-is,
+// undecoded. It must be registered last so every other, more specific
+// extractor gets first refusal; without it, a text/plain response (or
+// anything else CanHandle doesn't recognize) has no extractor at all.
+type PlainTextExtractor struct{}
+
+func NewPlainTextExtractor() *PlainTextExtractor { return &PlainTextExtractor{} }
+
+func (e *PlainTextExtractor) Name() string { return "plaintext" }
+
+func (e *PlainTextExtractor) CanHandle(_, _ string) bool { return true }
+
+func (e *PlainTextExtractor) Extract(_ context.Context, r io.Reader, u *url.URL) (Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Document{}, err
+	}
+	return Document{Title: u.String(), Content: string(data)}, nil
+}
@@ -0,0 +1,77 @@
+package extract
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ReadabilityExtractor picks the densest content subtree out of an HTML
+// document instead of dumping the whole body, so nav/sidebar cruft doesn't
+// end up in the prompt. It's the fallback extractor for anything that
+// looks like HTML.
+type ReadabilityExtractor struct{}
+
+func NewReadabilityExtractor() *ReadabilityExtractor { return &ReadabilityExtractor{} }
+
+func (e *ReadabilityExtractor) Name() string { return "readability" }
+
+func (e *ReadabilityExtractor) CanHandle(contentType, _ string) bool {
+	return contentType == "" || strings.Contains(contentType, "html")
+}
+
+func (e *ReadabilityExtractor) Extract(_ context.Context, r io.Reader, u *url.URL) (Document, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return Document{}, err
+	}
+
+	doc.Find("script,style,nav,header,footer,aside,form").Remove()
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	return Document{Title: title, Content: strings.TrimSpace(densestSubtree(doc))}, nil
+}
+
+// densestSubtree scores article/main/div candidates by text density (how
+// much of a candidate's text isn't inside a link) and returns the best
+// one's text, falling back to the whole body when nothing scores.
+//
+// Candidates are restricted to leaves of the set (no nested article/main/
+// div among them): a p-count*length product over every ancestor too is
+// monotonically non-decreasing as you walk up the DOM, since a wrapping
+// div's text and paragraphs are a superset of anything inside it, so the
+// outermost wrapper always wins and any sidebar/nav div siblings it wraps
+// leak straight through with it. Comparing only leaves
+// means a wrapping div around the real content and its sidebar never gets
+// to outscore either of them on its children's behalf.
+func densestSubtree(doc *goquery.Document) string {
+	best := ""
+	bestScore := -1.0
+
+	doc.Find("article, main, [role=main], div").Each(func(_ int, sel *goquery.Selection) {
+		if sel.Find("article, main, [role=main], div").Length() > 0 {
+			return
+		}
+
+		text := strings.TrimSpace(sel.Text())
+		if text == "" {
+			return
+		}
+
+		linkText := strings.TrimSpace(sel.Find("a").Text())
+		density := float64(len(text)-len(linkText)) / float64(len(text))
+		score := density * float64(len(text))
+		if score > bestScore {
+			bestScore = score
+			best = text
+		}
+	})
+
+	if best == "" {
+		return doc.Find("body").Text()
+	}
+	return best
+}
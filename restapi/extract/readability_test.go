@@ -0,0 +1,53 @@
+package extract
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDensestSubtreePrefersArticleOverWrappingSidebar(t *testing.T) {
+	html := `<html><body><div id="wrap">
+		<article><p>First real paragraph of the article.</p><p>Second real paragraph, with more words than the sidebar.</p><p>Third paragraph rounding out the article content.</p></article>
+		<div class="sidebar"><p><a href="/a">Link one</a></p><p><a href="/b">Link two</a></p></div>
+	</div></body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+
+	got := densestSubtree(doc)
+	if strings.Contains(got, "Link one") {
+		t.Fatalf("expected the sidebar to be excluded, got %q", got)
+	}
+	if !strings.Contains(got, "First real paragraph") {
+		t.Fatalf("expected the article content, got %q", got)
+	}
+}
+
+func TestReadabilityExtractorStripsWrappingDivsAndSidebars(t *testing.T) {
+	e := NewReadabilityExtractor()
+	html := `<html><head><title>Story</title></head><body><div id="wrap">
+		<article><p>First real paragraph of the article.</p><p>Second real paragraph, with more words than the sidebar.</p></article>
+		<div class="sidebar"><p><a href="/a">Link one</a></p><p><a href="/b">Link two</a></p></div>
+	</div></body></html>`
+
+	u, err := url.Parse("https://example.com/article")
+	if err != nil {
+		t.Fatalf("parsing url: %v", err)
+	}
+	doc, err := e.Extract(context.Background(), strings.NewReader(html), u)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if doc.Title != "Story" {
+		t.Fatalf("expected title %q, got %q", "Story", doc.Title)
+	}
+	if strings.Contains(doc.Content, "Link one") {
+		t.Fatalf("expected sidebar content to be excluded, got %q", doc.Content)
+	}
+}
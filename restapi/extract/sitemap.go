@@ -0,0 +1,110 @@
+package extract
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+type sitemapURLSet struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// maxSitemapEntries caps how many sitemap entries a single request will
+// fetch. The sitemap spec allows up to 50,000 URLs per file; fetching all
+// of them inside one HTTP handler would tie up the request indefinitely.
+const maxSitemapEntries = 200
+
+// sitemapConcurrency bounds how many sitemap entries are fetched at once.
+const sitemapConcurrency = 8
+
+// SitemapExtractor expands a sitemap.xml into a fetch of every page it
+// lists (up to maxSitemapEntries, sitemapConcurrency at a time) and
+// concatenates their extracted content.
+type SitemapExtractor struct {
+	registry *Registry
+}
+
+func NewSitemapExtractor(registry *Registry) *SitemapExtractor {
+	return &SitemapExtractor{registry: registry}
+}
+
+func (e *SitemapExtractor) Name() string { return "sitemap" }
+
+func (e *SitemapExtractor) CanHandle(_, rawURL string) bool {
+	return strings.Contains(strings.ToLower(rawURL), "sitemap") && strings.HasSuffix(strings.ToLower(rawURL), ".xml")
+}
+
+func (e *SitemapExtractor) Extract(ctx context.Context, r io.Reader, u *url.URL) (Document, error) {
+	var set sitemapURLSet
+	if err := xml.NewDecoder(r).Decode(&set); err != nil {
+		return Document{}, err
+	}
+
+	fetch := fetcherFromContext(ctx)
+	if fetch == nil {
+		return Document{}, fmt.Errorf("sitemap: no fetcher in context")
+	}
+
+	entries := set.URLs
+	truncated := len(entries) > maxSitemapEntries
+	if truncated {
+		entries = entries[:maxSitemapEntries]
+	}
+
+	contents := make([]string, len(entries))
+	sem := make(chan struct{}, sitemapConcurrency)
+	var wg sync.WaitGroup
+
+	for i, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, loc string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			contentType, body, err := fetch(ctx, loc)
+			if err != nil {
+				return
+			}
+			defer body.Close()
+
+			doc, err := e.registry.Extract(ctx, "", contentType, loc, fetch, body)
+			if err != nil {
+				return
+			}
+			contents[i] = doc.Content
+		}(i, entry.Loc)
+	}
+	wg.Wait()
+
+	var combined strings.Builder
+	for _, content := range contents {
+		if content == "" {
+			continue
+		}
+		if combined.Len() > 0 {
+			combined.WriteString("\n\n")
+		}
+		combined.WriteString(content)
+	}
+	if truncated {
+		fmt.Fprintf(&combined, "\n\n[sitemap truncated: %d of %d entries fetched]", maxSitemapEntries, len(set.URLs))
+	}
+
+	return Document{Title: u.String(), Content: combined.String()}, nil
+}
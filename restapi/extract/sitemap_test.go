@@ -0,0 +1,81 @@
+package extract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func buildSitemapXML(n int) string {
+	var b strings.Builder
+	b.WriteString("<urlset>")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "<url><loc>https://example.com/page%d.txt</loc></url>", i)
+	}
+	b.WriteString("</urlset>")
+	return b.String()
+}
+
+func TestSitemapExtractorFetchesEveryEntry(t *testing.T) {
+	reg := NewRegistry()
+	e := NewSitemapExtractor(reg)
+
+	var fetched int32
+	fetch := func(_ context.Context, rawURL string) (string, io.ReadCloser, error) {
+		atomic.AddInt32(&fetched, 1)
+		return "text/plain", io.NopCloser(strings.NewReader("content for " + rawURL)), nil
+	}
+
+	u, _ := url.Parse("https://example.com/sitemap.xml")
+	ctx := withFetcher(context.Background(), Fetcher(fetch))
+
+	doc, err := e.Extract(ctx, strings.NewReader(buildSitemapXML(5)), u)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if fetched != 5 {
+		t.Fatalf("expected 5 fetches, got %d", fetched)
+	}
+	if !strings.Contains(doc.Content, "page0.txt") || !strings.Contains(doc.Content, "page4.txt") {
+		t.Fatalf("expected combined content from all entries, got %q", doc.Content)
+	}
+}
+
+func TestSitemapExtractorCapsEntryCount(t *testing.T) {
+	reg := NewRegistry()
+	e := NewSitemapExtractor(reg)
+
+	var fetched int32
+	fetch := func(_ context.Context, rawURL string) (string, io.ReadCloser, error) {
+		atomic.AddInt32(&fetched, 1)
+		return "text/plain", io.NopCloser(strings.NewReader("x")), nil
+	}
+
+	u, _ := url.Parse("https://example.com/sitemap.xml")
+	ctx := withFetcher(context.Background(), Fetcher(fetch))
+
+	doc, err := e.Extract(ctx, strings.NewReader(buildSitemapXML(maxSitemapEntries+50)), u)
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if fetched != maxSitemapEntries {
+		t.Fatalf("expected exactly %d fetches, got %d", maxSitemapEntries, fetched)
+	}
+	if !strings.Contains(doc.Content, "truncated") {
+		t.Fatalf("expected a truncation note in the output, got %q", doc.Content)
+	}
+}
+
+func TestSitemapExtractorRequiresFetcherInContext(t *testing.T) {
+	reg := NewRegistry()
+	e := NewSitemapExtractor(reg)
+
+	u, _ := url.Parse("https://example.com/sitemap.xml")
+	if _, err := e.Extract(context.Background(), strings.NewReader(buildSitemapXML(1)), u); err == nil {
+		t.Fatal("expected an error when no Fetcher is present in context")
+	}
+}
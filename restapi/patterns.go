@@ -1,44 +1,84 @@
 package restapi
 
 import (
-	"strconv"
-	"bufio"
+	"container/list"
+	"context"
+	"encoding/json"
 	"log"
 	"net/http"
-	"os/exec"
 	"strings"
-	"github.com/PuerkitoBio/goquery"
-	"io"
+	"sync"
 	"time"
 
+	"github.com/danielmiessler/fabric/common"
+	"github.com/danielmiessler/fabric/core"
+	"github.com/danielmiessler/fabric/plugins/ai"
 	"github.com/danielmiessler/fabric/plugins/db/fsdb"
+	"github.com/danielmiessler/fabric/plugins/youtube"
+	"github.com/danielmiessler/fabric/restapi/extract"
+	"github.com/danielmiessler/fabric/restapi/sse"
+	"github.com/danielmiessler/fabric/restapi/streams"
+	"github.com/danielmiessler/fabric/restapi/webfetch"
 	"github.com/gin-gonic/gin"
 )
 
 // ExecuteRequest and ExecuteResponse definitions remain the same
 type ExecuteRequest struct {
-	Input   string `json:"input"`
-	Stream  bool   `json:"stream"`
-	Youtube bool   `json:"youtube"`
-	Model   string `json:"model,omitempty"`
-	ContextLength int `json:"context_length,omitempty"`
+	Input         string `json:"input"`
+	Stream        bool   `json:"stream"`
+	Youtube       bool   `json:"youtube"`
+	Model         string `json:"model,omitempty"`
+	ContextLength int    `json:"context_length,omitempty"`
+	// Extractor forces a specific extract.Extractor by name (e.g.
+	// "readability") instead of letting the registry pick one from the
+	// fetched content type.
+	Extractor string `json:"extractor,omitempty"`
+	// Fetch overrides webfetch's env-configured defaults for this request.
+	Fetch *FetchOptions `json:"fetch,omitempty"`
 }
 
+// FetchOptions overrides webfetch.Config on a single execute request. Zero
+// values fall back to the server's configured defaults.
+type FetchOptions struct {
+	// TimeoutSeconds overrides the per-request timeout; negative means
+	// unlimited.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	MaxRedirects   int `json:"max_redirects,omitempty"`
+	MaxRetries     int `json:"max_retries,omitempty"`
+}
+
+// ExecuteResponse is the v1, backward-compatible response shape. Clients
+// that opt into the richer shape (see isV2) also get TokenUsage and
+// FinishReason populated.
 type ExecuteResponse struct {
 	Content string `json:"content"`
+
+	TokenUsage   *TokenUsage `json:"token_usage,omitempty"`
+	FinishReason string      `json:"finish_reason,omitempty"`
+}
+
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 type PatternsHandler struct {
 	*StorageHandler[fsdb.Pattern]
-	patterns *fsdb.PatternsEntity
+	patterns       *fsdb.PatternsEntity
+	registry       *core.PluginRegistry
+	streamRegistry *streams.Registry
 }
 
-func NewPatternsHandler(r *gin.Engine, patterns *fsdb.PatternsEntity) (ret *PatternsHandler) {
+func NewPatternsHandler(r *gin.Engine, patterns *fsdb.PatternsEntity, registry *core.PluginRegistry, streamRegistry *streams.Registry) (ret *PatternsHandler) {
 	ret = &PatternsHandler{
 		StorageHandler: NewStorageHandler[fsdb.Pattern](r, "patterns", patterns),
 		patterns:       patterns,
+		registry:       registry,
+		streamRegistry: streamRegistry,
 	}
 	r.POST("/patterns/:name/execute", ret.Execute)
+	r.GET("/patterns/:name/ws", ret.ServeWS)
 	return
 }
 
@@ -53,8 +93,18 @@ func (h *PatternsHandler) Get(c *gin.Context) {
 	c.JSON(http.StatusOK, pattern)
 }
 
+// isV2 reports whether the caller opted into the richer response shape via
+// the v2 media type or the ?v2=true query parameter.
+func isV2(c *gin.Context) bool {
+	if c.Query("v2") == "true" {
+		return true
+	}
+	return strings.Contains(c.GetHeader("Accept"), "application/vnd.fabric.v2+json")
+}
+
 func (h *PatternsHandler) Execute(c *gin.Context) {
 	name := c.Param("name")
+	ctx := c.Request.Context()
 
 	var req ExecuteRequest
 	if err := c.BindJSON(&req); err != nil {
@@ -63,190 +113,241 @@ func (h *PatternsHandler) Execute(c *gin.Context) {
 		return
 	}
 
-	var cmd *exec.Cmd
+	message, err := h.resolveInput(ctx, name, &req)
+	if err != nil {
+		log.Printf("Error resolving input: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Prepare command based on input type and flags
-	if strings.HasPrefix(req.Input, "http://") || strings.HasPrefix(req.Input, "https://") {
-		if req.Youtube || name == "transcript" {
-			args := []string{"-y", req.Input, "--transcript"}
-			if name != "transcript" {
-				args = append(args, "--pattern", name)
-				if req.Stream {
-					args = append(args, "--stream")
-				}
-			}
-			if req.Model != "" {
-				args = append(args, "--model="+req.Model)
-			}
-			if req.ContextLength > 0 {
-				args = append(args, "--modelContextLength="+strconv.Itoa(req.ContextLength))			
-			}
-			cmd = exec.Command("/fabric", args...)
-		} else {
-			// For non-YouTube URLs, use getWebContent function
-			content, err := getWebContent(req.Input)
-			if err != nil {
-				log.Printf("Error fetching content: %v", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
+	chatter, err := h.registry.GetChatter(req.Model, req.ContextLength, ai.ChatStrategyRaw, req.Stream)
+	if err != nil {
+		log.Printf("Error getting chatter: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-			// Create pipe for fabric command
-			pipeReader, pipeWriter := io.Pipe()
-			go func() {
-				defer pipeWriter.Close()
-				io.WriteString(pipeWriter, content)
-			}()
+	chatReq := &common.ChatRequest{
+		PatternName: name,
+		Message:     message,
+	}
+	chatOpts := &common.ChatOptions{
+		Model:         req.Model,
+		ContextLength: req.ContextLength,
+	}
 
-			fabricArgs := []string{"--pattern", name}
-			if req.Stream {
-				fabricArgs = append(fabricArgs, "--stream")
-			}
-			if req.Model != "" {
-				fabricArgs = append(fabricArgs, "--model="+req.Model)
-			}
-			if req.ContextLength > 0 {
-				fabricArgs = append(fabricArgs, "--modelContextLength="+strconv.Itoa(req.ContextLength))			
-			}
-			cmd = exec.Command("/fabric", fabricArgs...)
-			cmd.Stdin = pipeReader
-		}
-	} else {
-		// Direct content input
-		fabricArgs := []string{"--pattern", name}
-		if req.Stream {
-			fabricArgs = append(fabricArgs, "--stream")
-		}
-		if req.Model != "" {
-			fabricArgs = append(fabricArgs, "--model="+req.Model)
-		}
-		if req.ContextLength > 0 {
-			fabricArgs = append(fabricArgs, "--modelContextLength="+strconv.Itoa(req.ContextLength))			
-		}
-		cmd = exec.Command("/fabric", fabricArgs...)
-		cmd.Stdin = strings.NewReader(req.Input)
+	if req.Stream {
+		h.streamExecute(c, ctx, chatter, chatReq, chatOpts)
+		return
 	}
 
-	log.Printf("Executing command: %v", cmd.Args)
+	session, tokenUsage, finishReason, err := chatter.Send(ctx, chatReq, chatOpts)
+	if err != nil {
+		log.Printf("Chatter failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	if req.Stream {
-		// Set required headers for SSE
-		c.Header("Content-Type", "text/event-stream")
-		c.Header("Cache-Control", "no-cache")
-		c.Header("Connection", "keep-alive")
-		c.Header("X-Accel-Buffering", "no") // Disable proxy buffering
-
-		// Flush headers immediately
-		c.Writer.Flush()
-
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			log.Printf("Error creating stdout pipe: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+	resp := ExecuteResponse{Content: session.GetLastMessage().Content}
+	if isV2(c) {
+		resp.FinishReason = finishReason
+		resp.TokenUsage = &TokenUsage{
+			PromptTokens:     tokenUsage.PromptTokens,
+			CompletionTokens: tokenUsage.CompletionTokens,
+			TotalTokens:      tokenUsage.TotalTokens,
 		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
 
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			log.Printf("Error creating stderr pipe: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+// streamExecute drains the chatter's token stream directly to the client
+// using the named token/message/usage/error/done SSE protocol, stopping as
+// soon as the request context is cancelled (e.g. the client disconnected).
+// Unlike the old line-buffered "message" events, "token" carries each raw
+// delta chunk as-is so partial tokens are never discarded, and "done" is
+// always emitted last so the client can tell clean completion from a drop.
+//
+// Every event is also published to a streams.Stream so a dropped
+// connection can reattach via GET /streams/:token instead of losing the
+// rest of the run.
+func (h *PatternsHandler) streamExecute(c *gin.Context, parent context.Context, chatter *core.Chatter, chatReq *common.ChatRequest, chatOpts *common.ChatOptions) {
+	stream, ctx := h.streamRegistry.New(parent)
 
-		if err := cmd.Start(); err != nil {
-			log.Printf("Error starting command: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable proxy buffering
+	c.Header("Fabric-Stream-Token", stream.Token)
+	c.Writer.Flush()
+
+	enc := sse.NewEncoder(c.Writer)
+	emit(enc, stream, sse.EventStream, sse.StreamEvent{Token: stream.Token})
+
+	tokens, errs := chatter.Stream(ctx, chatReq, chatOpts)
+
+	var aggregated strings.Builder
+	finishReason := "stop"
+	usage := sse.UsageEvent{Model: chatOpts.Model}
 
-		// Handle stderr in a goroutine
-		go func() {
-			scanner := bufio.NewScanner(stderr)
-			for scanner.Scan() {
-				log.Printf("stderr: %s", scanner.Text())
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Client disconnected, cancelling generation: %v", ctx.Err())
+			// Without a done event the stream is never marked s.done, so it
+			// never expires (Registry.Sweep/Get both check s.done) and any
+			// client reattached via GET /streams/:token hangs forever.
+			emit(enc, stream, sse.EventDone, sse.DoneEvent{FinishReason: "cancelled"})
+			return
+		case err, ok := <-errs:
+			if !ok {
+				// A nilled channel blocks forever in a select, so once errs
+				// closes this case stops firing instead of busy-spinning.
+				errs = nil
+				continue
 			}
-		}()
-
-		// Create a channel to signal when streaming is done
-		done := make(chan bool)
-
-		// Stream stdout in a goroutine
-		go func() {
-			scanner := bufio.NewScanner(stdout)
-			for scanner.Scan() {
-				line := scanner.Text()
-				// Send SSE message and flush immediately
-				c.SSEvent("message", ExecuteResponse{Content: line})
-				c.Writer.Flush()
+			if err != nil {
+				emit(enc, stream, sse.EventError, sse.ErrorEvent{Code: "generation_failed", Message: err.Error()})
+				emit(enc, stream, sse.EventDone, sse.DoneEvent{FinishReason: "error"})
+				return
+			}
+		case chunk, ok := <-tokens:
+			if !ok {
+				emit(enc, stream, sse.EventMessage, sse.MessageEvent{Content: aggregated.String()})
+				emit(enc, stream, sse.EventUsage, usage)
+				emit(enc, stream, sse.EventDone, sse.DoneEvent{FinishReason: finishReason})
+				return
 			}
-			if err := scanner.Err(); err != nil {
-				log.Printf("Error reading stdout: %v", err)
-				c.SSEvent("error", gin.H{"error": err.Error()})
-				c.Writer.Flush()
+			aggregated.WriteString(chunk.Content)
+			emit(enc, stream, sse.EventToken, sse.TokenEvent{Content: chunk.Content})
+			if chunk.FinishReason != "" {
+				finishReason = chunk.FinishReason
 			}
-			done <- true
-		}()
-
-		// Wait for command completion
-		if err := cmd.Wait(); err != nil {
-			log.Printf("Command failed: %v", err)
-			c.SSEvent("error", gin.H{"error": err.Error()})
-			c.Writer.Flush()
+			usage.PromptTokens = chunk.Usage.PromptTokens
+			usage.CompletionTokens = chunk.Usage.CompletionTokens
+			usage.TotalTokens = chunk.Usage.TotalTokens
 		}
+	}
+}
 
-		// Wait for streaming to complete
-		<-done
+// emit writes an event to the connected client and, if stream is non-nil,
+// publishes the same event to it for later reattachment/replay.
+func emit(enc *sse.Encoder, stream *streams.Stream, eventType sse.EventType, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshalling %s event: %v", eventType, err)
+		return
+	}
+	enc.Encode(eventType, json.RawMessage(payload))
+	if stream != nil {
+		stream.Publish(&sse.Event{Type: eventType, Data: payload})
+	}
+}
 
-	} else {
-		// For non-streaming requests
-		var output []byte
-		var err error
+// resolveInput turns the request into the text that should be sent to the
+// model, fetching the URL or YouTube transcript first when needed.
+func (h *PatternsHandler) resolveInput(ctx context.Context, name string, req *ExecuteRequest) (string, error) {
+	if !strings.HasPrefix(req.Input, "http://") && !strings.HasPrefix(req.Input, "https://") {
+		return req.Input, nil
+	}
 
-		output, err = cmd.CombinedOutput()
-		if err != nil {
-			log.Printf("Command failed: %v\nOutput: %s", err, string(output))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	if req.Youtube || name == "transcript" {
+		yt := youtube.NewClient()
+		return yt.GrabTranscript(ctx, req.Input)
+	}
+
+	return getWebContent(ctx, req.Input, req.Extractor, req.Fetch)
+}
+
+// defaultFetcher is the env-configured webfetch.Fetcher used for requests
+// that don't override any fetch options.
+var defaultFetcher = webfetch.New(webfetch.ConfigFromEnv())
+
+// maxOverrideFetchers caps how many distinct FetchOptions overrides keep a
+// cached Fetcher alive at once. Config is built straight from client-
+// supplied ints (timeout/redirects/retries), so without a cap a caller
+// could grow overrideFetchers without bound simply by varying one of them
+// per request, each entry pinning its own ETag cache.
+const maxOverrideFetchers = 64
+
+// overrideFetchers caches one webfetch.Fetcher per distinct effective
+// Config, evicting the least-recently-used entry past maxOverrideFetchers,
+// so requests sharing the same FetchOptions override (the common case:
+// everyone overrides the same knob, e.g. max_retries) share a single ETag
+// cache and per-host concurrency limiter instead of each getting a
+// throwaway Fetcher, while a caller that varies the override every request
+// can't grow this without bound.
+var (
+	overrideFetchersMu    sync.Mutex
+	overrideFetchers      = make(map[webfetch.Config]*list.Element)
+	overrideFetchersOrder = list.New()
+)
+
+type overrideFetcherEntry struct {
+	cfg     webfetch.Config
+	fetcher *webfetch.Fetcher
+}
+
+func fetcherFor(cfg webfetch.Config) *webfetch.Fetcher {
+	overrideFetchersMu.Lock()
+	defer overrideFetchersMu.Unlock()
 
-		c.JSON(http.StatusOK, ExecuteResponse{
-			Content: string(output),
-		})
+	if el, ok := overrideFetchers[cfg]; ok {
+		overrideFetchersOrder.MoveToFront(el)
+		return el.Value.(*overrideFetcherEntry).fetcher
 	}
+
+	f := webfetch.New(cfg)
+	el := overrideFetchersOrder.PushFront(&overrideFetcherEntry{cfg: cfg, fetcher: f})
+	overrideFetchers[cfg] = el
+
+	if overrideFetchersOrder.Len() > maxOverrideFetchers {
+		oldest := overrideFetchersOrder.Back()
+		overrideFetchersOrder.Remove(oldest)
+		delete(overrideFetchers, oldest.Value.(*overrideFetcherEntry).cfg)
+	}
+
+	return f
 }
 
-func getWebContent(url string) (string, error) {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+// webExtractors holds fabric's default content extractors (readability,
+// feed, pdf, sitemap, plaintext). Nested fetches (sitemap entries) go
+// through whichever Fetcher is passed to Extract for that request, so they
+// inherit its context and any per-request FetchOptions.
+var webExtractors = extract.NewRegistry()
+
+// getWebContent fetches rawURL and extracts prompt-ready text from it,
+// picking the extractor from the response's content type unless extractor
+// forces a specific one by name. opts overrides the fetcher's configured
+// timeout/redirect/retry defaults for this request only.
+func getWebContent(ctx context.Context, rawURL, extractor string, opts *FetchOptions) (string, error) {
+	fetcher := defaultFetcher
+	if opts != nil {
+		cfg := webfetch.ConfigFromEnv()
+		if opts.TimeoutSeconds != 0 {
+			if opts.TimeoutSeconds < 0 {
+				cfg.Timeout = 0
+			} else {
+				cfg.Timeout = time.Duration(opts.TimeoutSeconds) * time.Second
+			}
+		}
+		if opts.MaxRedirects > 0 {
+			cfg.MaxRedirects = opts.MaxRedirects
+		}
+		if opts.MaxRetries > 0 {
+			cfg.MaxRetries = opts.MaxRetries
+		}
+		fetcher = fetcherFor(cfg)
 	}
 
-	resp, err := client.Get(url)
+	contentType, body, err := fetcher.Fetch(ctx, rawURL)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
+	defer body.Close()
 
-	contentType := resp.Header.Get("Content-Type")
-	
-	// Handle HTML content
-	if strings.Contains(contentType, "text/html") {
-		doc, err := goquery.NewDocumentFromReader(resp.Body)
-		if err != nil {
-			return "", err
-		}
-		
-		// Remove unwanted elements
-		doc.Find("script,style,nav,header,footer").Remove()
-		
-		// Extract text content
-		return strings.TrimSpace(doc.Find("body").Text()), nil
-	}
-	
-	// Handle plain text content
-	content, err := io.ReadAll(resp.Body)
+	doc, err := webExtractors.Extract(ctx, extractor, contentType, rawURL, fetcher.Fetch, body)
 	if err != nil {
 		return "", err
 	}
-	
-	return string(content), nil
+	return doc.Content, nil
 }
@@ -0,0 +1,91 @@
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Event is a single decoded SSE event with its raw JSON payload still
+// attached, so callers can route on Type before unmarshalling.
+type Event struct {
+	Type EventType
+	Data json.RawMessage
+}
+
+// maxLineSize is the largest single SSE line (e.g. a "message" event
+// carrying a whole aggregated transcript) the Decoder will accept, well
+// past bufio.Scanner's 64KB default.
+const maxLineSize = 10 << 20 // 10 MiB
+
+// Decoder reads events written by an Encoder back off the wire.
+type Decoder struct {
+	scanner *bufio.Scanner
+}
+
+// NewDecoder wraps r, typically an *http.Response.Body.
+func NewDecoder(r io.Reader) *Decoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &Decoder{scanner: scanner}
+}
+
+// Next reads the next event, returning io.EOF once the stream ends.
+func (d *Decoder) Next() (*Event, error) {
+	var event EventType
+	var data strings.Builder
+
+	for d.scanner.Scan() {
+		line := d.scanner.Text()
+		switch {
+		case line == "":
+			if event == "" {
+				continue
+			}
+			return &Event{Type: event, Data: json.RawMessage(data.String())}, nil
+		case strings.HasPrefix(line, "event:"):
+			event = EventType(strings.TrimSpace(strings.TrimPrefix(line, "event:")))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Token unmarshals the event's payload as a TokenEvent.
+func (e *Event) Token() (t TokenEvent, err error) {
+	err = json.Unmarshal(e.Data, &t)
+	return
+}
+
+// Message unmarshals the event's payload as a MessageEvent.
+func (e *Event) Message() (m MessageEvent, err error) {
+	err = json.Unmarshal(e.Data, &m)
+	return
+}
+
+// Usage unmarshals the event's payload as a UsageEvent.
+func (e *Event) Usage() (u UsageEvent, err error) {
+	err = json.Unmarshal(e.Data, &u)
+	return
+}
+
+// Error unmarshals the event's payload as an ErrorEvent.
+func (e *Event) Error() (er ErrorEvent, err error) {
+	err = json.Unmarshal(e.Data, &er)
+	return
+}
+
+// Done unmarshals the event's payload as a DoneEvent.
+func (e *Event) Done() (d DoneEvent, err error) {
+	err = json.Unmarshal(e.Data, &d)
+	return
+}
@@ -0,0 +1,126 @@
+// Package sse implements fabric's server-sent event protocol: a small set
+// of named events (token/message/usage/error/done) shared by every
+// streaming handler, plus an Encoder and Decoder so Go clients don't have
+// to reimplement the wire format themselves.
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// EventType is one of the named SSE events fabric emits while streaming a
+// pattern execution.
+type EventType string
+
+const (
+	// EventToken carries a single raw delta chunk as it is generated, with
+	// no line buffering.
+	EventToken EventType = "token"
+	// EventMessage carries the full aggregated text once generation ends.
+	EventMessage EventType = "message"
+	// EventUsage carries prompt/completion token counts and the model used.
+	EventUsage EventType = "usage"
+	// EventError carries a structured error.
+	EventError EventType = "error"
+	// EventDone is always the final event of a stream, successful or not.
+	EventDone EventType = "done"
+	// EventStream announces the stream token a client can use to reattach
+	// to this execution later via GET /streams/:token.
+	EventStream EventType = "stream"
+)
+
+// TokenEvent is the payload of an EventToken.
+type TokenEvent struct {
+	Content string `json:"content"`
+}
+
+// MessageEvent is the payload of an EventMessage.
+type MessageEvent struct {
+	Content string `json:"content"`
+}
+
+// UsageEvent is the payload of an EventUsage.
+type UsageEvent struct {
+	Model            string `json:"model"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	TotalTokens      int    `json:"total_tokens"`
+}
+
+// ErrorEvent is the payload of an EventError.
+type ErrorEvent struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// DoneEvent is the payload of an EventDone.
+type DoneEvent struct {
+	FinishReason string `json:"finish_reason,omitempty"`
+}
+
+// StreamEvent is the payload of an EventStream.
+type StreamEvent struct {
+	Token string `json:"token"`
+}
+
+// Encoder writes named SSE events to an underlying writer, flushing after
+// every event so proxies and browsers see them as soon as they're written.
+type Encoder struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+// NewEncoder wraps w. If w also implements http.Flusher (as gin's
+// ResponseWriter does), each Encode flushes immediately.
+func NewEncoder(w io.Writer) *Encoder {
+	flusher, _ := w.(http.Flusher)
+	return &Encoder{w: w, flusher: flusher}
+}
+
+// Encode writes a single named event with data marshalled as its JSON body.
+func (e *Encoder) Encode(event EventType, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+	return nil
+}
+
+// Token encodes an EventToken.
+func (e *Encoder) Token(content string) error {
+	return e.Encode(EventToken, TokenEvent{Content: content})
+}
+
+// Message encodes an EventMessage.
+func (e *Encoder) Message(content string) error {
+	return e.Encode(EventMessage, MessageEvent{Content: content})
+}
+
+// Usage encodes an EventUsage.
+func (e *Encoder) Usage(usage UsageEvent) error {
+	return e.Encode(EventUsage, usage)
+}
+
+// Error encodes an EventError.
+func (e *Encoder) Error(code, message string) error {
+	return e.Encode(EventError, ErrorEvent{Code: code, Message: message})
+}
+
+// Done encodes the terminal EventDone.
+func (e *Encoder) Done(finishReason string) error {
+	return e.Encode(EventDone, DoneEvent{FinishReason: finishReason})
+}
+
+// Stream encodes the initial EventStream announcing a reattach token.
+func (e *Encoder) Stream(token string) error {
+	return e.Encode(EventStream, StreamEvent{Token: token})
+}
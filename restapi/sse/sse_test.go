@@ -0,0 +1,127 @@
+package sse
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+
+	if err := enc.Token("Hel"); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if err := enc.Token("lo"); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if err := enc.Message("Hello"); err != nil {
+		t.Fatalf("Message: %v", err)
+	}
+	if err := enc.Usage(UsageEvent{Model: "gpt-4", PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}); err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if err := enc.Done("stop"); err != nil {
+		t.Fatalf("Done: %v", err)
+	}
+
+	dec := NewDecoder(&buf)
+
+	ev, err := dec.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if ev.Type != EventToken {
+		t.Fatalf("expected token event, got %s", ev.Type)
+	}
+	if tok, err := ev.Token(); err != nil || tok.Content != "Hel" {
+		t.Fatalf("unexpected token payload: %+v, %v", tok, err)
+	}
+
+	ev, err = dec.Next()
+	if err != nil || ev.Type != EventToken {
+		t.Fatalf("expected second token event, got %+v, %v", ev, err)
+	}
+
+	ev, err = dec.Next()
+	if err != nil || ev.Type != EventMessage {
+		t.Fatalf("expected message event, got %+v, %v", ev, err)
+	}
+	if msg, err := ev.Message(); err != nil || msg.Content != "Hello" {
+		t.Fatalf("unexpected message payload: %+v, %v", msg, err)
+	}
+
+	ev, err = dec.Next()
+	if err != nil || ev.Type != EventUsage {
+		t.Fatalf("expected usage event, got %+v, %v", ev, err)
+	}
+	if usage, err := ev.Usage(); err != nil || usage.TotalTokens != 12 {
+		t.Fatalf("unexpected usage payload: %+v, %v", usage, err)
+	}
+
+	ev, err = dec.Next()
+	if err != nil || ev.Type != EventDone {
+		t.Fatalf("expected done event, got %+v, %v", ev, err)
+	}
+	if done, err := ev.Done(); err != nil || done.FinishReason != "stop" {
+		t.Fatalf("unexpected done payload: %+v, %v", done, err)
+	}
+
+	if _, err := dec.Next(); err == nil {
+		t.Fatalf("expected EOF, got nil error")
+	}
+}
+
+func TestDecoderErrorEvent(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Error("rate_limited", "too many requests"); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	dec := NewDecoder(&buf)
+	ev, err := dec.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if ev.Type != EventError {
+		t.Fatalf("expected error event, got %s", ev.Type)
+	}
+	errEv, err := ev.Error()
+	if err != nil {
+		t.Fatalf("Error payload: %v", err)
+	}
+	if errEv.Code != "rate_limited" || errEv.Message != "too many requests" {
+		t.Fatalf("unexpected error payload: %+v", errEv)
+	}
+}
+
+// TestDecoderLargePayload guards against bufio.Scanner's default 64KB line
+// limit: a "message" event carrying a whole aggregated transcript can
+// easily exceed that on a long pattern run.
+func TestDecoderLargePayload(t *testing.T) {
+	large := strings.Repeat("a", 5<<20) // 5 MiB, past the 64KB default
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Message(large); err != nil {
+		t.Fatalf("Message: %v", err)
+	}
+
+	dec := NewDecoder(&buf)
+	ev, err := dec.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if ev.Type != EventMessage {
+		t.Fatalf("expected message event, got %s", ev.Type)
+	}
+	msg, err := ev.Message()
+	if err != nil {
+		t.Fatalf("Message payload: %v", err)
+	}
+	if msg.Content != large {
+		t.Fatalf("payload truncated: got %d bytes, want %d", len(msg.Content), len(large))
+	}
+}
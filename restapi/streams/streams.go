@@ -0,0 +1,211 @@
+// Package streams implements resumable pattern-execution streams, modeled
+// on Docker's container-attach semantics and Fauna's stream tokens: a long
+// running execution is identified by an opaque token so a dropped
+// connection (a flaky mobile client, a proxy timeout) can reattach and pick
+// up everything it missed instead of losing the run.
+package streams
+
+import (
+	"container/ring"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/danielmiessler/fabric/restapi/sse"
+)
+
+// ErrNotFound is returned when a token is unknown or has expired.
+var ErrNotFound = errors.New("stream not found")
+
+// bufferSize is how many recent events a Stream replays to a client that
+// (re)attaches, beyond whatever arrives live afterwards.
+const bufferSize = 256
+
+// DefaultTTL is how long a completed stream stays reattachable before the
+// registry evicts it.
+const DefaultTTL = 10 * time.Minute
+
+// Stream is a single server-side pattern execution that any number of SSE
+// clients can attach and reattach to.
+type Stream struct {
+	Token string
+
+	mu     sync.Mutex
+	buf    *ring.Ring
+	done   bool
+	doneAt time.Time
+	cancel context.CancelFunc
+	subs   map[chan *sse.Event]struct{}
+}
+
+func newStream(token string, cancel context.CancelFunc) *Stream {
+	return &Stream{
+		Token:  token,
+		buf:    ring.New(bufferSize),
+		cancel: cancel,
+		subs:   make(map[chan *sse.Event]struct{}),
+	}
+}
+
+// Publish appends ev to the replay buffer and fans it out to every
+// currently-attached subscriber. The EventDone event marks the stream
+// complete and starts its TTL countdown.
+//
+// Fan-out is non-blocking: a subscriber whose buffered channel is full
+// (a stalled reattach client, exactly the case this subsystem exists for)
+// is dropped and its channel closed rather than stalling delivery to every
+// other subscriber, including the original caller still generating tokens.
+func (s *Stream) Publish(ev *sse.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf.Value = ev
+	s.buf = s.buf.Next()
+	if ev.Type == sse.EventDone {
+		s.done = true
+		s.doneAt = time.Now()
+	}
+
+	for ch := range s.subs {
+		select {
+		case ch <- ev:
+		default:
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns the events buffered so far (oldest first) plus a
+// channel that receives every event published from this point on. The
+// caller must invoke the returned unsubscribe func once it stops reading.
+func (s *Stream) Subscribe() (live <-chan *sse.Event, backlog []*sse.Event, unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backlog = make([]*sse.Event, 0, bufferSize)
+	s.buf.Do(func(v interface{}) {
+		if v == nil {
+			return
+		}
+		backlog = append(backlog, v.(*sse.Event))
+	})
+
+	ch := make(chan *sse.Event, bufferSize)
+	s.subs[ch] = struct{}{}
+
+	unsubscribe = func() {
+		s.mu.Lock()
+		delete(s.subs, ch)
+		s.mu.Unlock()
+	}
+	return ch, backlog, unsubscribe
+}
+
+// Cancel aborts the context the underlying generation is running under.
+func (s *Stream) Cancel() {
+	s.cancel()
+}
+
+func (s *Stream) expired(ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done && time.Since(s.doneAt) > ttl
+}
+
+// Registry tracks in-flight and recently-completed streams by token.
+type Registry struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	streams map[string]*Stream
+}
+
+// NewRegistry creates a registry that expires completed streams ttl after
+// their done event. A non-positive ttl falls back to DefaultTTL.
+//
+// It also starts a background sweep every ttl/2 so streams that finish and
+// are never reattached to (the common case for a one-shot SSE client) are
+// still evicted; relying solely on the lazy eviction in Get would leak one
+// entry per execution that nobody ever reattaches to.
+func NewRegistry(ttl time.Duration) *Registry {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	r := &Registry{ttl: ttl, streams: make(map[string]*Stream)}
+
+	go func() {
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for range ticker.C {
+			r.Sweep()
+		}
+	}()
+
+	return r
+}
+
+// New starts tracking a new stream and returns it along with a context
+// derived from parent that the caller should run generation under; calling
+// Cancel on the stream (or on the registry, by token) cancels that context.
+func (r *Registry) New(parent context.Context) (*Stream, context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	s := newStream(newToken(), cancel)
+
+	r.mu.Lock()
+	r.streams[s.Token] = s
+	r.mu.Unlock()
+
+	return s, ctx
+}
+
+// Get looks up a stream by token, evicting it first if its TTL has
+// elapsed.
+func (r *Registry) Get(token string) (*Stream, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.streams[token]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if s.expired(r.ttl) {
+		delete(r.streams, token)
+		return nil, ErrNotFound
+	}
+	return s, nil
+}
+
+// Cancel looks up token and cancels its underlying generation.
+func (r *Registry) Cancel(token string) error {
+	s, err := r.Get(token)
+	if err != nil {
+		return err
+	}
+	s.Cancel()
+	return nil
+}
+
+// Sweep removes completed streams whose TTL has elapsed. NewRegistry
+// already runs it in the background every ttl/2; it can also be called
+// directly.
+func (r *Registry) Sweep() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for token, s := range r.streams {
+		if s.expired(r.ttl) {
+			delete(r.streams, token)
+		}
+	}
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,101 @@
+package streams
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/restapi/sse"
+)
+
+func tokenEvent(content string) *sse.Event {
+	return &sse.Event{Type: sse.EventToken, Data: []byte(`{"content":"` + content + `"}`)}
+}
+
+func TestRegistryNewGetCancel(t *testing.T) {
+	reg := NewRegistry(time.Minute)
+
+	stream, ctx := reg.New(context.Background())
+	if stream.Token == "" {
+		t.Fatal("expected a non-empty token")
+	}
+
+	got, err := reg.Get(stream.Token)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != stream {
+		t.Fatal("Get returned a different stream than New produced")
+	}
+
+	if err := reg.Cancel(stream.Token); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("expected context to be cancelled")
+	}
+
+	if _, err := reg.Get("does-not-exist"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStreamSubscribeReplaysBacklogThenLive(t *testing.T) {
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newStream("tok", cancel)
+
+	stream.Publish(tokenEvent("a"))
+	stream.Publish(tokenEvent("b"))
+
+	live, backlog, unsubscribe := stream.Subscribe()
+	defer unsubscribe()
+
+	if len(backlog) != 2 {
+		t.Fatalf("expected 2 backlog events, got %d", len(backlog))
+	}
+
+	stream.Publish(tokenEvent("c"))
+
+	select {
+	case ev := <-live:
+		if ev.Type != sse.EventToken {
+			t.Fatalf("expected token event, got %s", ev.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for live event")
+	}
+}
+
+func TestStreamPublishDropsSlowSubscriberWithoutBlocking(t *testing.T) {
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := newStream("tok", cancel)
+
+	live, _, unsubscribe := stream.Subscribe()
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		// Never read from live: fill its buffer and publish one more to
+		// force the drop path. If Publish blocked, this goroutine would
+		// hang and the test would time out below instead of completing.
+		for i := 0; i < bufferSize+1; i++ {
+			stream.Publish(tokenEvent("x"))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publish blocked on a slow subscriber instead of dropping it")
+	}
+
+	// The dropped subscriber's channel is closed once its buffer fills;
+	// draining it should terminate rather than block forever.
+	for range live {
+	}
+}
@@ -0,0 +1,79 @@
+package restapi
+
+import (
+	"net/http"
+
+	"github.com/danielmiessler/fabric/restapi/sse"
+	"github.com/danielmiessler/fabric/restapi/streams"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamsHandler exposes the resumable-stream reattach/cancel endpoints
+// backed by the same streams.Registry PatternsHandler publishes into.
+type StreamsHandler struct {
+	registry *streams.Registry
+}
+
+func NewStreamsHandler(r *gin.Engine, registry *streams.Registry) (ret *StreamsHandler) {
+	ret = &StreamsHandler{registry: registry}
+	r.GET("/streams/:token", ret.Attach)
+	r.DELETE("/streams/:token", ret.Cancel)
+	return
+}
+
+// Attach replays everything buffered for the token so far, then streams
+// live events until the execution finishes or the client disconnects.
+func (h *StreamsHandler) Attach(c *gin.Context) {
+	token := c.Param("token")
+
+	stream, err := h.registry.Get(token)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Writer.Flush()
+
+	enc := sse.NewEncoder(c.Writer)
+	live, backlog, unsubscribe := stream.Subscribe()
+	defer unsubscribe()
+
+	for _, ev := range backlog {
+		enc.Encode(ev.Type, ev.Data)
+		if ev.Type == sse.EventDone {
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-live:
+			if !ok {
+				// Publish dropped us for falling behind; nothing more to
+				// read from this attach.
+				return
+			}
+			enc.Encode(ev.Type, ev.Data)
+			if ev.Type == sse.EventDone {
+				return
+			}
+		}
+	}
+}
+
+// Cancel aborts the underlying generation for token.
+func (h *StreamsHandler) Cancel(c *gin.Context) {
+	token := c.Param("token")
+	if err := h.registry.Cancel(token); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
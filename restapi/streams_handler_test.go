@@ -0,0 +1,71 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielmiessler/fabric/restapi/sse"
+	"github.com/danielmiessler/fabric/restapi/streams"
+	"github.com/gin-gonic/gin"
+)
+
+// TestCancelThenReattachSeesDoneEvent guards the chunk0-3 bug where
+// cancelling a stream's generation never produced a done event: a stream
+// is never marked done until EventDone is published (Registry.Sweep/Get
+// both gate on s.done), so without it DELETE /streams/:token leaves any
+// reattaching GET hanging forever and the stream leaked in the registry.
+// PatternsHandler.streamExecute can't be exercised directly here (it needs
+// core.PluginRegistry/fsdb, not present in this tree), so this drives the
+// same registry+StreamsHandler pairing it runs against, publishing the
+// done event the way streamExecute's ctx.Done() branch now does.
+func TestCancelThenReattachSeesDoneEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := streams.NewRegistry(time.Minute)
+	r := gin.New()
+	NewStreamsHandler(r, registry)
+
+	stream, ctx := registry.New(context.Background())
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/streams/"+stream.Token, nil)
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 from Cancel, got %d", rec.Code)
+	}
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("expected Cancel to cancel the stream's context")
+	}
+
+	// This is what streamExecute's ctx.Done() branch does post-fix: emit a
+	// done event so the stream is marked done and any reattach unblocks.
+	stream.Publish(&sse.Event{Type: sse.EventDone, Data: []byte(`{"finish_reason":"cancelled"}`)})
+
+	attachRec := httptest.NewRecorder()
+	attachReq := httptest.NewRequest(http.MethodGet, "/streams/"+stream.Token, nil)
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(attachRec, attachReq)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Attach never returned after the done event; a client reattaching post-cancel would hang forever")
+	}
+
+	if attachRec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from Attach, got %d", attachRec.Code)
+	}
+	if !strings.Contains(attachRec.Body.String(), "event: done") {
+		t.Fatalf("expected a done event in the replayed backlog, got %q", attachRec.Body.String())
+	}
+}
@@ -0,0 +1,65 @@
+package webfetch
+
+import (
+	"container/list"
+	"sync"
+)
+
+type cacheEntry struct {
+	key         string
+	etag        string
+	contentType string
+	body        []byte
+}
+
+// lru is a small fixed-capacity cache keyed by request URL, so repeated
+// pattern invocations against the same page can revalidate via ETag
+// instead of refetching the whole body.
+type lru struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *lru) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry), true
+}
+
+func (c *lru) put(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[entry.key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(entry)
+	c.items[entry.key] = el
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*cacheEntry).key)
+		}
+	}
+}
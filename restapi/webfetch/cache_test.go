@@ -0,0 +1,42 @@
+package webfetch
+
+import "testing"
+
+func TestLRUGetPutAndEviction(t *testing.T) {
+	c := newLRU(2)
+
+	c.put(&cacheEntry{key: "a", etag: "etag-a"})
+	c.put(&cacheEntry{key: "b", etag: "etag-b"})
+
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+
+	// b is now the least recently used; adding c should evict it.
+	c.put(&cacheEntry{key: "c", etag: "etag-c"})
+
+	if _, ok := c.get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("expected a to survive eviction (touched more recently than b)")
+	}
+	if _, ok := c.get("c"); !ok {
+		t.Fatal("expected c to be cached")
+	}
+}
+
+func TestLRUPutOverwritesExistingKey(t *testing.T) {
+	c := newLRU(2)
+
+	c.put(&cacheEntry{key: "a", etag: "old"})
+	c.put(&cacheEntry{key: "a", etag: "new"})
+
+	entry, ok := c.get("a")
+	if !ok {
+		t.Fatal("expected a to be cached")
+	}
+	if entry.etag != "new" {
+		t.Fatalf("expected overwritten etag %q, got %q", "new", entry.etag)
+	}
+}
@@ -0,0 +1,92 @@
+package webfetch
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config controls how a Fetcher talks to origins: timeout, redirect/retry
+// budgets, per-host concurrency, body size, and the User-Agent it presents.
+type Config struct {
+	// Timeout is the per-request timeout. Zero means unlimited, for
+	// fabric users running long transcripts against slow origins who'd
+	// rather wait than fail the run (mirroring the Zeno pattern).
+	Timeout time.Duration
+	// MaxRedirects caps how many redirects a single fetch follows.
+	MaxRedirects int
+	// MaxRetries caps retry attempts on 5xx/429 responses, honoring
+	// Retry-After when the origin sends one and backing off
+	// exponentially otherwise.
+	MaxRetries int
+	// PerHostConcurrency caps in-flight requests to a single host.
+	PerHostConcurrency int
+	// MaxBodyBytes caps how much of a response body is read. Zero means
+	// unlimited.
+	MaxBodyBytes int64
+	// UserAgent overrides the default User-Agent header.
+	UserAgent string
+	// CacheSize is how many responses the ETag-aware cache keeps. Zero
+	// disables caching.
+	CacheSize int
+}
+
+// DefaultConfig matches getWebContent's previous hardcoded 30s timeout,
+// plus sane defaults for the new knobs.
+func DefaultConfig() Config {
+	return Config{
+		Timeout:            30 * time.Second,
+		MaxRedirects:       20,
+		MaxRetries:         3,
+		PerHostConcurrency: 4,
+		MaxBodyBytes:       10 << 20, // 10 MiB
+		UserAgent:          "fabric/1.0",
+		CacheSize:          128,
+	}
+}
+
+// ConfigFromEnv overlays DefaultConfig with any FABRIC_WEBFETCH_* env vars
+// that are set. A negative timeout means unlimited.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+
+	if v, ok := os.LookupEnv("FABRIC_WEBFETCH_TIMEOUT_SECONDS"); ok {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			if seconds < 0 {
+				cfg.Timeout = 0
+			} else {
+				cfg.Timeout = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if v, ok := os.LookupEnv("FABRIC_WEBFETCH_MAX_REDIRECTS"); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.MaxRedirects = n
+		}
+	}
+	if v, ok := os.LookupEnv("FABRIC_WEBFETCH_MAX_RETRIES"); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.MaxRetries = n
+		}
+	}
+	if v, ok := os.LookupEnv("FABRIC_WEBFETCH_PER_HOST_CONCURRENCY"); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.PerHostConcurrency = n
+		}
+	}
+	if v, ok := os.LookupEnv("FABRIC_WEBFETCH_MAX_BODY_BYTES"); ok {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.MaxBodyBytes = n
+		}
+	}
+	if v, ok := os.LookupEnv("FABRIC_WEBFETCH_USER_AGENT"); ok && v != "" {
+		cfg.UserAgent = v
+	}
+	if v, ok := os.LookupEnv("FABRIC_WEBFETCH_CACHE_SIZE"); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.CacheSize = n
+		}
+	}
+
+	return cfg
+}
@@ -0,0 +1,164 @@
+// Package webfetch provides a configurable HTTP client for getWebContent:
+// a request timeout (or none, for long-running fetches), a redirect cap, a
+// retry budget with backoff on 5xx/429, a per-host concurrency cap, a max
+// body size, and an ETag-aware cache so repeated pattern invocations
+// against the same URL don't refetch it.
+package webfetch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Fetcher is a configurable replacement for getWebContent's old inline
+// *http.Client.
+type Fetcher struct {
+	cfg    Config
+	client *http.Client
+	cache  *lru
+
+	mu       sync.Mutex
+	hostSems map[string]chan struct{}
+}
+
+// New builds a Fetcher from cfg.
+func New(cfg Config) *Fetcher {
+	f := &Fetcher{
+		cfg:      cfg,
+		hostSems: make(map[string]chan struct{}),
+	}
+
+	f.client = &http.Client{
+		Timeout: cfg.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= cfg.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", cfg.MaxRedirects)
+			}
+			return nil
+		},
+	}
+
+	if cfg.CacheSize > 0 {
+		f.cache = newLRU(cfg.CacheSize)
+	}
+
+	return f
+}
+
+func (f *Fetcher) hostSemaphore(host string) chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sem, ok := f.hostSems[host]
+	if !ok {
+		limit := f.cfg.PerHostConcurrency
+		if limit <= 0 {
+			limit = 1
+		}
+		sem = make(chan struct{}, limit)
+		f.hostSems[host] = sem
+	}
+	return sem
+}
+
+// Fetch retrieves rawURL, retrying on 5xx/429 with exponential backoff
+// (honoring Retry-After) up to cfg.MaxRetries times, respecting the
+// per-host concurrency cap and the configured max body size.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (contentType string, body io.ReadCloser, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sem := f.hostSemaphore(u.Host)
+	sem <- struct{}{}
+	defer func() { <-sem }()
+
+	var cached *cacheEntry
+	if f.cache != nil {
+		if entry, ok := f.cache.get(rawURL); ok {
+			cached = entry
+		}
+	}
+
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if reqErr != nil {
+			return "", nil, reqErr
+		}
+		if f.cfg.UserAgent != "" {
+			req.Header.Set("User-Agent", f.cfg.UserAgent)
+		}
+		if cached != nil && cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+
+		resp, err = f.client.Do(req)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			break
+		}
+		if attempt >= f.cfg.MaxRetries {
+			defer resp.Body.Close()
+			return "", nil, fmt.Errorf("fetching %s: status %d after %d attempts", rawURL, resp.StatusCode, attempt+1)
+		}
+
+		wait := retryDelay(attempt, resp.Header.Get("Retry-After"))
+		resp.Body.Close()
+		select {
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.contentType, io.NopCloser(bytes.NewReader(cached.body)), nil
+	}
+	if resp.StatusCode >= 400 {
+		return "", nil, fmt.Errorf("fetching %s: status %d", rawURL, resp.StatusCode)
+	}
+
+	reader := io.Reader(resp.Body)
+	if f.cfg.MaxBodyBytes > 0 {
+		reader = io.LimitReader(resp.Body, f.cfg.MaxBodyBytes)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ct := resp.Header.Get("Content-Type")
+	if f.cache != nil {
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			f.cache.put(&cacheEntry{key: rawURL, etag: etag, contentType: ct, body: data})
+		}
+	}
+
+	return ct, io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// retryDelay honors a numeric Retry-After header when present, otherwise
+// backs off exponentially starting at 500ms.
+func retryDelay(attempt int, retryAfter string) time.Duration {
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return time.Duration(500*math.Pow(2, float64(attempt))) * time.Millisecond
+}
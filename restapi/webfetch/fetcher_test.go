@@ -0,0 +1,134 @@
+package webfetch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 2
+	cfg.CacheSize = 8
+	return cfg
+}
+
+func TestFetcherRetriesOnServerError(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	f := New(testConfig())
+	contentType, body, err := f.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	defer body.Close()
+
+	if contentType != "text/plain" {
+		t.Fatalf("unexpected content type: %q", contentType)
+	}
+	data, _ := io.ReadAll(body)
+	if string(data) != "ok" {
+		t.Fatalf("unexpected body: %q", data)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestFetcherGivesUpAfterMaxRetries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	f := New(testConfig())
+	if _, _, err := f.Fetch(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+}
+
+func TestFetcherRevalidatesViaETagCache(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == "v1" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", "v1")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("fresh"))
+	}))
+	defer srv.Close()
+
+	f := New(testConfig())
+
+	_, body, err := f.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("first Fetch: %v", err)
+	}
+	body.Close()
+
+	contentType, body, err := f.Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("second Fetch: %v", err)
+	}
+	defer body.Close()
+
+	data, _ := io.ReadAll(body)
+	if string(data) != "fresh" {
+		t.Fatalf("expected cached body %q, got %q", "fresh", data)
+	}
+	if contentType != "text/plain" {
+		t.Fatalf("unexpected content type: %q", contentType)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestFetcherStopsAtRedirectCap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	cfg := testConfig()
+	cfg.MaxRedirects = 1
+	f := New(cfg)
+
+	if _, _, err := f.Fetch(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected an error after exceeding the redirect cap")
+	}
+}
+
+func TestFetcherRespectsContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cfg := testConfig()
+	cfg.MaxRetries = 5
+	f := New(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, _, err := f.Fetch(ctx, srv.URL); err == nil {
+		t.Fatal("expected an error once the context is cancelled mid-retry")
+	}
+}
@@ -0,0 +1,192 @@
+package restapi
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/danielmiessler/fabric/common"
+	"github.com/danielmiessler/fabric/plugins/ai"
+	"github.com/danielmiessler/fabric/restapi/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsClientMessage is one frame of the client->server protocol spoken over
+// GET /patterns/:name/ws: {type:"start"|"cancel"|"followup", ...}.
+type wsClientMessage struct {
+	Type          string `json:"type"`
+	Input         string `json:"input,omitempty"`
+	Model         string `json:"model,omitempty"`
+	ContextLength int    `json:"context_length,omitempty"`
+}
+
+// wsEvent is the server->client wire shape: the same named event types the
+// SSE protocol uses, carried as a JSON envelope instead of an SSE frame.
+type wsEvent struct {
+	Type sse.EventType `json:"type"`
+	Data interface{}   `json:"data"`
+}
+
+// wsConn guards a *websocket.Conn's writes with a mutex. gorilla/websocket
+// only allows one writer goroutine at a time, but ServeWS's main loop
+// (bad-message errors) and the per-turn goroutine it spawns both write to
+// the same connection, so every write has to go through this.
+type wsConn struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (c *wsConn) writeEvent(eventType sse.EventType, data interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.conn.WriteJSON(wsEvent{Type: eventType, Data: data}); err != nil {
+		log.Printf("Error writing websocket event: %v", err)
+	}
+}
+
+// ServeWS upgrades to a WebSocket and speaks a small interactive protocol:
+// client start/cancel/followup messages in, the same token/usage/error/done
+// events the SSE endpoint emits out. It reuses the same in-process chatter
+// plumbing as Execute, so a "cancel" actually aborts generation rather than
+// just closing a pipe, and unlike SSE the client can send a followup turn
+// without opening a new connection.
+func (h *PatternsHandler) ServeWS(c *gin.Context) {
+	name := c.Param("name")
+
+	rawConn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("Error upgrading to websocket: %v", err)
+		return
+	}
+	defer rawConn.Close()
+	conn := &wsConn{conn: rawConn}
+
+	msgs := make(chan wsClientMessage)
+	go func() {
+		defer close(msgs)
+		for {
+			var msg wsClientMessage
+			if err := rawConn.ReadJSON(&msg); err != nil {
+				return
+			}
+			msgs <- msg
+		}
+	}()
+
+	var cancel context.CancelFunc
+	var turnDone chan struct{}
+	var history strings.Builder
+
+	for msg := range msgs {
+		switch msg.Type {
+		case "start", "followup":
+			if cancel != nil {
+				cancel()
+				<-turnDone
+			}
+
+			if msg.Type == "start" {
+				history.Reset()
+			}
+
+			turnCtx, cancelFunc := context.WithCancel(c.Request.Context())
+			cancel = cancelFunc
+			done := make(chan struct{})
+			turnDone = done
+
+			go func(m wsClientMessage) {
+				defer close(done)
+				h.runWSTurn(turnCtx, conn, name, m, &history)
+			}(msg)
+		case "cancel":
+			if cancel != nil {
+				cancel()
+			}
+		default:
+			conn.writeEvent(sse.EventError, sse.ErrorEvent{Code: "bad_message", Message: "unknown message type: " + msg.Type})
+		}
+	}
+
+	if cancel != nil {
+		cancel()
+	}
+	if turnDone != nil {
+		<-turnDone
+	}
+}
+
+// runWSTurn drives a single start/followup turn, writing events to conn as
+// they're produced and stopping as soon as ctx is cancelled. history carries
+// the prior turns of this connection's conversation (empty for "start"); a
+// "followup" message threads it into the request so it's an actual
+// continuation rather than an unrelated fresh turn, and this turn's own
+// input/output is appended to history once it completes cleanly.
+func (h *PatternsHandler) runWSTurn(ctx context.Context, conn *wsConn, name string, msg wsClientMessage, history *strings.Builder) {
+	chatter, err := h.registry.GetChatter(msg.Model, msg.ContextLength, ai.ChatStrategyRaw, true)
+	if err != nil {
+		conn.writeEvent(sse.EventError, sse.ErrorEvent{Code: "chatter_unavailable", Message: err.Error()})
+		conn.writeEvent(sse.EventDone, sse.DoneEvent{FinishReason: "error"})
+		return
+	}
+
+	message := msg.Input
+	if history.Len() > 0 {
+		message = history.String() + "\n\nUser: " + msg.Input
+	}
+
+	chatReq := &common.ChatRequest{PatternName: name, Message: message}
+	chatOpts := &common.ChatOptions{Model: msg.Model, ContextLength: msg.ContextLength}
+
+	tokens, errs := chatter.Stream(ctx, chatReq, chatOpts)
+
+	var aggregated strings.Builder
+	finishReason := "stop"
+	usage := sse.UsageEvent{Model: msg.Model}
+
+	for {
+		select {
+		case <-ctx.Done():
+			conn.writeEvent(sse.EventDone, sse.DoneEvent{FinishReason: "cancelled"})
+			return
+		case err, ok := <-errs:
+			if !ok {
+				// A nilled channel blocks forever in a select, so once errs
+				// closes this case stops firing instead of busy-spinning.
+				errs = nil
+				continue
+			}
+			if err != nil {
+				conn.writeEvent(sse.EventError, sse.ErrorEvent{Code: "generation_failed", Message: err.Error()})
+				conn.writeEvent(sse.EventDone, sse.DoneEvent{FinishReason: "error"})
+				return
+			}
+		case chunk, ok := <-tokens:
+			if !ok {
+				conn.writeEvent(sse.EventUsage, usage)
+				conn.writeEvent(sse.EventDone, sse.DoneEvent{FinishReason: finishReason})
+				if history.Len() > 0 {
+					history.WriteString("\n\n")
+				}
+				history.WriteString("User: " + msg.Input + "\nAssistant: " + aggregated.String())
+				return
+			}
+			aggregated.WriteString(chunk.Content)
+			conn.writeEvent(sse.EventToken, sse.TokenEvent{Content: chunk.Content})
+			if chunk.FinishReason != "" {
+				finishReason = chunk.FinishReason
+			}
+			usage.PromptTokens = chunk.Usage.PromptTokens
+			usage.CompletionTokens = chunk.Usage.CompletionTokens
+			usage.TotalTokens = chunk.Usage.TotalTokens
+		}
+	}
+}